@@ -0,0 +1,86 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package api holds the scheduler's policy configuration file schema: which
+// predicates and priorities to register by name, and which extenders to
+// call out to, read from disk by the scheduler binary and passed to
+// factory.ConfigFactory.CreateFromConfig.
+package api
+
+// Policy describes the scheduler's configurable behavior: the fit
+// predicates and priority functions to register by name in place of an
+// algorithm provider's defaults, plus any extenders to consult alongside
+// them.
+type Policy struct {
+	// Predicates lists the fit predicates to register, by name, instead of
+	// an algorithm provider's defaults.
+	Predicates []PredicatePolicy
+	// Priorities lists the priority functions to register, by name and
+	// weight, instead of an algorithm provider's defaults.
+	Priorities []PriorityPolicy
+	// ExtenderConfigs lists the out-of-process extenders to consult, in
+	// order, after the in-process predicates and priorities run.
+	ExtenderConfigs []ExtenderConfig
+}
+
+// PredicatePolicy names a fit predicate to register.
+type PredicatePolicy struct {
+	Name string
+}
+
+// PriorityPolicy names a priority function to register and the weight its
+// scores are multiplied by before being summed with every other priority
+// source.
+type PriorityPolicy struct {
+	Name   string
+	Weight int
+}
+
+// ExtenderConfig describes a single out-of-process scheduler extender,
+// reachable over HTTP at URLPrefix.
+type ExtenderConfig struct {
+	// URLPrefix is the base URL the extender's Filter/Prioritize endpoints
+	// are resolved against.
+	URLPrefix string
+	// FilterVerb is the HTTP path, relative to URLPrefix, the extender
+	// exposes for filtering nodes. Left empty, Filter is skipped.
+	FilterVerb string
+	// PrioritizeVerb is the HTTP path, relative to URLPrefix, the extender
+	// exposes for scoring nodes. Left empty, Prioritize is skipped.
+	PrioritizeVerb string
+	// Weight is the multiplier applied to this extender's Prioritize scores
+	// before they are combined with every other priority source.
+	Weight int
+	// TLSConfig is the transport security configuration used when calling
+	// the extender. May be nil to use the default transport.
+	TLSConfig *ExtenderTLSConfig
+	// NodeCacheCapable indicates the extender maintains its own cache of
+	// node information and only needs node names, not full node objects,
+	// in Filter/Prioritize requests. HTTPExtender sends full node objects
+	// whether or not this is set.
+	NodeCacheCapable bool
+	// Ignorable indicates a Filter error or non-2xx response from this
+	// extender should merely be logged rather than failing the pod's
+	// scheduling attempt outright.
+	Ignorable bool
+}
+
+// ExtenderTLSConfig holds the TLS options for a single extender's HTTP
+// client.
+type ExtenderTLSConfig struct {
+	// Insecure skips verification of the extender's server certificate.
+	Insecure bool
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation checks a scheduler api.Policy for errors before it is
+// handed to factory.ConfigFactory.CreateFromConfig.
+package validation
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/scheduler/api"
+)
+
+// ValidatePolicy checks policy for errors that would otherwise surface as
+// confusing failures deep in scheduler construction: duplicate or unnamed
+// predicates/priorities, and extenders missing the fields required to call
+// them.
+func ValidatePolicy(policy api.Policy) error {
+	predicateNames := map[string]bool{}
+	for _, predicate := range policy.Predicates {
+		if predicate.Name == "" {
+			return fmt.Errorf("predicate policy entry has no name")
+		}
+		if predicateNames[predicate.Name] {
+			return fmt.Errorf("predicate %q is registered more than once", predicate.Name)
+		}
+		predicateNames[predicate.Name] = true
+	}
+
+	priorityNames := map[string]bool{}
+	for _, priority := range policy.Priorities {
+		if priority.Name == "" {
+			return fmt.Errorf("priority policy entry has no name")
+		}
+		if priorityNames[priority.Name] {
+			return fmt.Errorf("priority %q is registered more than once", priority.Name)
+		}
+		priorityNames[priority.Name] = true
+	}
+
+	for _, extender := range policy.ExtenderConfigs {
+		if extender.URLPrefix == "" {
+			return fmt.Errorf("extender config has no URLPrefix")
+		}
+		if extender.FilterVerb == "" && extender.PrioritizeVerb == "" {
+			return fmt.Errorf("extender %q has neither a FilterVerb nor a PrioritizeVerb", extender.URLPrefix)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/scheduler/api"
+)
+
+func TestValidatePolicyValid(t *testing.T) {
+	policy := api.Policy{
+		Predicates: []api.PredicatePolicy{{Name: "PodFitsResources"}},
+		Priorities: []api.PriorityPolicy{{Name: "LeastRequestedPriority", Weight: 1}},
+		ExtenderConfigs: []api.ExtenderConfig{
+			{URLPrefix: "http://extender", FilterVerb: "filter"},
+		},
+	}
+	if err := ValidatePolicy(policy); err != nil {
+		t.Fatalf("ValidatePolicy(valid) = %v, want nil", err)
+	}
+}
+
+func TestValidatePolicyDuplicatePredicate(t *testing.T) {
+	policy := api.Policy{
+		Predicates: []api.PredicatePolicy{{Name: "PodFitsResources"}, {Name: "PodFitsResources"}},
+	}
+	if err := ValidatePolicy(policy); err == nil {
+		t.Fatalf("ValidatePolicy(duplicate predicate) = nil, want error")
+	}
+}
+
+func TestValidatePolicyExtenderMissingURLPrefix(t *testing.T) {
+	policy := api.Policy{
+		ExtenderConfigs: []api.ExtenderConfig{{FilterVerb: "filter"}},
+	}
+	if err := ValidatePolicy(policy); err == nil {
+		t.Fatalf("ValidatePolicy(extender without URLPrefix) = nil, want error")
+	}
+}
+
+func TestValidatePolicyExtenderMissingVerbs(t *testing.T) {
+	policy := api.Policy{
+		ExtenderConfigs: []api.ExtenderConfig{{URLPrefix: "http://extender"}},
+	}
+	if err := ValidatePolicy(policy); err == nil {
+		t.Fatalf("ValidatePolicy(extender without verbs) = nil, want error")
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+)
+
+// defaultEquivalenceCacheSize bounds how many (predicate, equivalence
+// class, node) results are memoized at once. Large ReplicaSets/DaemonSets
+// mean most pods share a handful of equivalence classes, so this is
+// comfortably larger than the node count of most clusters.
+const defaultEquivalenceCacheSize = 4096
+
+// equivalencePodSignature is the subset of a pod's spec that affects
+// whether a fit predicate can pass or fail. Pods that hash to the same
+// value are interchangeable from a scheduling point of view, so a
+// predicate result computed for one can be reused for all the others.
+type equivalencePodSignature struct {
+	Requests     []api.ResourceList `json:"requests"`
+	NodeSelector map[string]string  `json:"nodeSelector,omitempty"`
+	Tolerations  []api.Toleration   `json:"tolerations,omitempty"`
+	Affinity     string             `json:"affinity,omitempty"`
+	VolumeClaims []string           `json:"volumeClaims,omitempty"`
+}
+
+// equivalenceHash returns the equivalence class a pod belongs to for the
+// purposes of predicate caching.
+func equivalenceHash(pod *api.Pod) string {
+	sig := equivalencePodSignature{
+		NodeSelector: pod.Spec.NodeSelector,
+		Affinity:     pod.Annotations["scheduler.alpha.kubernetes.io/affinity"],
+	}
+	for _, c := range pod.Spec.Containers {
+		sig.Requests = append(sig.Requests, c.Resources.Requests)
+	}
+	sig.Tolerations = pod.Spec.Tolerations
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			sig.VolumeClaims = append(sig.VolumeClaims, v.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	// The signature only needs to be stable and collision-resistant, not
+	// human readable, so a JSON encoding fed through sha256 is enough.
+	encoded, err := json.Marshal(sig)
+	if err != nil {
+		// Fall back to a per-pod unique value so a marshal failure can
+		// only ever cost a cache miss, never an incorrect hit.
+		return pod.Namespace + "/" + pod.Name
+	}
+	sum := sha256.Sum256(encoded)
+	return fmt.Sprintf("%x", sum)
+}
+
+type equivalenceCacheKey struct {
+	node       string
+	predicate  string
+	equivClass string
+	generation int64
+}
+
+// equivalenceCache memoizes fit-predicate results per (predicate,
+// equivalence class, node), bounded to defaultEquivalenceCacheSize entries
+// with least-recently-used eviction. Entries are tagged with the node's
+// generation at insertion time; bumping a node's generation (via
+// InvalidateNode) makes every entry recorded against the old generation
+// unreachable without having to walk and delete them individually.
+type equivalenceCache struct {
+	mu sync.Mutex
+
+	capacity int
+	ll       *list.List
+	items    map[equivalenceCacheKey]*list.Element
+
+	generations map[string]int64
+}
+
+type equivalenceCacheEntry struct {
+	key  equivalenceCacheKey
+	fits bool
+}
+
+// newEquivalenceCache creates an empty equivalenceCache.
+func newEquivalenceCache() *equivalenceCache {
+	return &equivalenceCache{
+		capacity:    defaultEquivalenceCacheSize,
+		ll:          list.New(),
+		items:       map[equivalenceCacheKey]*list.Element{},
+		generations: map[string]int64{},
+	}
+}
+
+func (c *equivalenceCache) currentGeneration(node string) int64 {
+	return c.generations[node]
+}
+
+// Lookup returns a cached predicate result for pod's equivalence class on
+// node, if one is still valid.
+func (c *equivalenceCache) Lookup(predicate, equivClass, node string) (fits bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := equivalenceCacheKey{node: node, predicate: predicate, equivClass: equivClass, generation: c.currentGeneration(node)}
+	elem, found := c.items[key]
+	if !found {
+		return false, false
+	}
+	c.ll.MoveToFront(elem)
+	return elem.Value.(*equivalenceCacheEntry).fits, true
+}
+
+// Update stores a predicate result for pod's equivalence class on node.
+func (c *equivalenceCache) Update(predicate, equivClass, node string, fits bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := equivalenceCacheKey{node: node, predicate: predicate, equivClass: equivClass, generation: c.currentGeneration(node)}
+	if elem, found := c.items[key]; found {
+		elem.Value.(*equivalenceCacheEntry).fits = fits
+		c.ll.MoveToFront(elem)
+		return
+	}
+	elem := c.ll.PushFront(&equivalenceCacheEntry{key: key, fits: fits})
+	c.items[key] = elem
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*equivalenceCacheEntry).key)
+		}
+	}
+}
+
+// InvalidateNode bumps node's generation, invalidating every predicate
+// result cached against it without needing to find and delete each entry
+// up front; they are simply never looked up again and age out of the LRU.
+func (c *equivalenceCache) InvalidateNode(node string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generations[node]++
+}
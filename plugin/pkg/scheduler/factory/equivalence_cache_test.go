@@ -0,0 +1,88 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import "testing"
+
+func TestEquivalenceCacheLookupMiss(t *testing.T) {
+	c := newEquivalenceCache()
+	if _, ok := c.Lookup("PredicateA", "classA", "node1"); ok {
+		t.Fatalf("Lookup on empty cache returned a hit")
+	}
+}
+
+func TestEquivalenceCacheUpdateThenLookup(t *testing.T) {
+	c := newEquivalenceCache()
+	c.Update("PredicateA", "classA", "node1", true)
+
+	fits, ok := c.Lookup("PredicateA", "classA", "node1")
+	if !ok || !fits {
+		t.Fatalf("Lookup = (%v, %v), want (true, true)", fits, ok)
+	}
+
+	// A different node, predicate or equivalence class is a distinct key.
+	if _, ok := c.Lookup("PredicateA", "classA", "node2"); ok {
+		t.Fatalf("Lookup on a different node returned a hit")
+	}
+	if _, ok := c.Lookup("PredicateB", "classA", "node1"); ok {
+		t.Fatalf("Lookup on a different predicate returned a hit")
+	}
+}
+
+func TestEquivalenceCacheInvalidateNode(t *testing.T) {
+	c := newEquivalenceCache()
+	c.Update("PredicateA", "classA", "node1", true)
+
+	c.InvalidateNode("node1")
+
+	if _, ok := c.Lookup("PredicateA", "classA", "node1"); ok {
+		t.Fatalf("Lookup after InvalidateNode returned a hit")
+	}
+
+	// A stale entry is invisible, not overwritten; writing a fresh result
+	// for the new generation must be independently lookupable.
+	c.Update("PredicateA", "classA", "node1", false)
+	fits, ok := c.Lookup("PredicateA", "classA", "node1")
+	if !ok || fits {
+		t.Fatalf("Lookup after re-Update = (%v, %v), want (false, true)", fits, ok)
+	}
+}
+
+func TestEquivalenceCacheLRUEviction(t *testing.T) {
+	c := newEquivalenceCache()
+	c.capacity = 2
+
+	c.Update("PredicateA", "class1", "node1", true)
+	c.Update("PredicateA", "class2", "node1", true)
+	// Touch class1 so it's more recently used than class2.
+	if _, ok := c.Lookup("PredicateA", "class1", "node1"); !ok {
+		t.Fatalf("Lookup(class1) missed before eviction")
+	}
+	// Inserting a third entry should evict the least-recently-used one,
+	// class2, not class1.
+	c.Update("PredicateA", "class3", "node1", true)
+
+	if _, ok := c.Lookup("PredicateA", "class2", "node1"); ok {
+		t.Fatalf("Lookup(class2) hit after it should have been evicted")
+	}
+	if _, ok := c.Lookup("PredicateA", "class1", "node1"); !ok {
+		t.Fatalf("Lookup(class1) missed; it should have survived eviction")
+	}
+	if _, ok := c.Lookup("PredicateA", "class3", "node1"); !ok {
+		t.Fatalf("Lookup(class3) missed; it was just inserted")
+	}
+}
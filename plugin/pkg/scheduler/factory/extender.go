@@ -0,0 +1,162 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	algorithm "github.com/GoogleCloudPlatform/kubernetes/pkg/scheduler"
+	schedulerapi "github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/scheduler/api"
+)
+
+// extenderHTTPTimeout bounds how long the scheduler waits for a single
+// extender call before giving up on it.
+const extenderHTTPTimeout = 5 * time.Second
+
+// SchedulerExtender lets an out-of-tree process participate in scheduling
+// decisions without recompiling the scheduler binary: after the in-process
+// predicates and priorities run, every configured extender gets a chance
+// to further filter the surviving nodes and contribute to their scores.
+type SchedulerExtender interface {
+	// Name identifies the extender in logs and error messages.
+	Name() string
+	// Filter narrows nodes down to those the extender also considers
+	// viable for pod.
+	Filter(pod *api.Pod, nodes []api.Node) ([]api.Node, error)
+	// Prioritize returns a score for each node; the caller multiplies it
+	// by Weight() before adding it to the node's in-process priority sum.
+	Prioritize(pod *api.Pod, nodes []api.Node) (algorithm.HostPriorityList, error)
+	// Weight is the multiplier applied to this extender's Prioritize
+	// scores before they are combined with every other priority source.
+	Weight() int
+	// Ignorable reports whether a Filter error or non-2xx response from
+	// this extender should merely be logged rather than failing the pod's
+	// scheduling attempt outright.
+	Ignorable() bool
+}
+
+// extenderArgs is the payload POSTed to an extender's filter/prioritize
+// endpoints.
+type extenderArgs struct {
+	Pod   api.Pod    `json:"pod"`
+	Nodes []api.Node `json:"nodes"`
+}
+
+// extenderFilterResult is the payload an extender's filter endpoint is
+// expected to return.
+type extenderFilterResult struct {
+	Nodes       []api.Node `json:"nodes"`
+	FailedNodes []string   `json:"failedNodes,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// HTTPExtender calls out to a SchedulerExtender over HTTP, as configured
+// via schedulerapi.ExtenderConfig in the scheduler policy file.
+type HTTPExtender struct {
+	urlPrefix        string
+	filterVerb       string
+	prioritizeVerb   string
+	weight           int
+	client           *http.Client
+	nodeCacheCapable bool
+	ignorable        bool
+}
+
+// NewHTTPExtender builds an HTTPExtender from its policy configuration.
+func NewHTTPExtender(config schedulerapi.ExtenderConfig) (*HTTPExtender, error) {
+	transport := &http.Transport{}
+	if config.TLSConfig != nil {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: config.TLSConfig.Insecure}
+	}
+	return &HTTPExtender{
+		urlPrefix:        config.URLPrefix,
+		filterVerb:       config.FilterVerb,
+		prioritizeVerb:   config.PrioritizeVerb,
+		weight:           config.Weight,
+		nodeCacheCapable: config.NodeCacheCapable,
+		ignorable:        config.Ignorable,
+		client:           &http.Client{Transport: transport, Timeout: extenderHTTPTimeout},
+	}, nil
+}
+
+func (h *HTTPExtender) Name() string    { return h.urlPrefix }
+func (h *HTTPExtender) Weight() int     { return h.weight }
+func (h *HTTPExtender) Ignorable() bool { return h.ignorable }
+
+func (h *HTTPExtender) send(verb string, args *extenderArgs, result interface{}) error {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	url := h.urlPrefix + "/" + verb
+	resp, err := h.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("extender %v returned status %v", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (h *HTTPExtender) Filter(pod *api.Pod, nodes []api.Node) ([]api.Node, error) {
+	if h.filterVerb == "" {
+		return nodes, nil
+	}
+	result := extenderFilterResult{}
+	args := &extenderArgs{Pod: *pod, Nodes: nodes}
+	if err := h.send(h.filterVerb, args, &result); err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("extender %v: %v", h.urlPrefix, result.Error)
+	}
+	return result.Nodes, nil
+}
+
+func (h *HTTPExtender) Prioritize(pod *api.Pod, nodes []api.Node) (algorithm.HostPriorityList, error) {
+	if h.prioritizeVerb == "" {
+		return nil, nil
+	}
+	var result algorithm.HostPriorityList
+	args := &extenderArgs{Pod: *pod, Nodes: nodes}
+	if err := h.send(h.prioritizeVerb, args, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// buildExtenders turns the policy's extender configuration into callable
+// SchedulerExtenders.
+func buildExtenders(configs []schedulerapi.ExtenderConfig) ([]SchedulerExtender, error) {
+	var extenders []SchedulerExtender
+	for _, config := range configs {
+		extender, err := NewHTTPExtender(config)
+		if err != nil {
+			return nil, err
+		}
+		extenders = append(extenders, extender)
+	}
+	return extenders, nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	schedulerapi "github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/scheduler/api"
+)
+
+func TestHTTPExtenderFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var args extenderArgs
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(extenderFilterResult{Nodes: args.Nodes[:1]})
+	}))
+	defer server.Close()
+
+	extender, err := NewHTTPExtender(schedulerapi.ExtenderConfig{URLPrefix: server.URL, FilterVerb: "filter"})
+	if err != nil {
+		t.Fatalf("NewHTTPExtender: %v", err)
+	}
+
+	nodes := []api.Node{
+		{ObjectMeta: api.ObjectMeta{Name: "node1"}},
+		{ObjectMeta: api.ObjectMeta{Name: "node2"}},
+	}
+	filtered, err := extender.Filter(&api.Pod{}, nodes)
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].Name != "node1" {
+		t.Fatalf("Filter result = %v, want [node1]", filtered)
+	}
+}
+
+func TestHTTPExtenderFilterSkippedWithoutVerb(t *testing.T) {
+	extender, err := NewHTTPExtender(schedulerapi.ExtenderConfig{URLPrefix: "http://unused"})
+	if err != nil {
+		t.Fatalf("NewHTTPExtender: %v", err)
+	}
+
+	nodes := []api.Node{{ObjectMeta: api.ObjectMeta{Name: "node1"}}}
+	filtered, err := extender.Filter(&api.Pod{}, nodes)
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	if len(filtered) != 1 {
+		t.Fatalf("Filter with no FilterVerb should pass nodes through unchanged, got %v", filtered)
+	}
+}
+
+func TestHTTPExtenderFilterError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(extenderFilterResult{Error: "boom"})
+	}))
+	defer server.Close()
+
+	extender, err := NewHTTPExtender(schedulerapi.ExtenderConfig{URLPrefix: server.URL, FilterVerb: "filter"})
+	if err != nil {
+		t.Fatalf("NewHTTPExtender: %v", err)
+	}
+
+	if _, err := extender.Filter(&api.Pod{}, []api.Node{{ObjectMeta: api.ObjectMeta{Name: "node1"}}}); err == nil {
+		t.Fatalf("Filter with extender-reported error = nil, want error")
+	}
+}
@@ -19,6 +19,7 @@ limitations under the License.
 package factory
 
 import (
+	"fmt"
 	"math/rand"
 	"sync"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/client/cache"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client/record"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/controller/framework"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/fields"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/labels"
@@ -34,6 +36,7 @@ import (
 	"github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/scheduler"
 	schedulerapi "github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/scheduler/api"
 	"github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/scheduler/api/validation"
+	"github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/scheduler/schedulercache"
 
 	"github.com/golang/glog"
 )
@@ -41,8 +44,9 @@ import (
 // ConfigFactory knows how to fill out a scheduler config with its support functions.
 type ConfigFactory struct {
 	Client *client.Client
-	// queue for pods that need scheduling
-	PodQueue *cache.FIFO
+	// queue for pods that need scheduling; orders by priority and parks
+	// pods that failed a scheduling attempt until cluster state changes
+	PodQueue *PriorityPodQueue
 	// a means to list all known scheduled pods.
 	ScheduledPodLister *cache.StoreToPodLister
 	// a means to list all known scheduled pods and pods assumed to have been scheduled.
@@ -52,29 +56,70 @@ type ConfigFactory struct {
 	// a means to list all services
 	ServiceLister *cache.StoreToServiceLister
 
+	// ParallelWorkers is how many goroutines fan out fit-predicate
+	// evaluation across nodes for a single pod. Defaults to
+	// defaultParallelWorkers.
+	ParallelWorkers int
+
+	// LeaderElection, when set, makes Create/CreateFromKeys block on
+	// acquiring this lease before starting the reflectors and
+	// scheduledPodPopulator, so multiple scheduler replicas can run for
+	// HA with only the lease holder actively scheduling.
+	LeaderElection *LeaderElectionConfig
+
+	// Recorder records scheduling events (Scheduled, FailedScheduling) on
+	// pods, the same way kubectl describe pod surfaces them for any other
+	// controller action.
+	Recorder record.EventRecorder
+
 	// Close this to stop all reflectors
 	StopEverything chan struct{}
 
 	scheduledPodPopulator *framework.Controller
-	modeler               scheduler.SystemModeler
+	// schedulerCache holds the precomputed per-node snapshots (requested
+	// and allocatable resources, pods) that predicates and priorities run
+	// against, kept current from watch events and from pods the algorithm
+	// has assumed bound ahead of the apiserver write landing.
+	schedulerCache schedulercache.Cache
+	// equivalenceCache memoizes fit-predicate results per equivalence
+	// class and node so that large ReplicaSets/DaemonSets whose pods hash
+	// to the same equivalence class don't each re-run every predicate.
+	equivalenceCache *equivalenceCache
+	// schedulingLatency tracks, per pod key, when NextPod last popped it
+	// off the active queue so the binder can report end-to-end scheduling
+	// latency once it lands.
+	schedulingLatency *schedulingLatencyTracker
 }
 
 // Initializes the factory.
 func NewConfigFactory(client *client.Client) *ConfigFactory {
 	c := &ConfigFactory{
 		Client:             client,
-		PodQueue:           cache.NewFIFO(cache.MetaNamespaceKeyFunc),
+		PodQueue:           NewPriorityPodQueue(),
 		ScheduledPodLister: &cache.StoreToPodLister{},
 		NodeLister:         &cache.StoreToNodeLister{cache.NewStore(cache.MetaNamespaceKeyFunc)},
 		ServiceLister:      &cache.StoreToServiceLister{cache.NewStore(cache.MetaNamespaceKeyFunc)},
+		ParallelWorkers:    defaultParallelWorkers,
 		StopEverything:     make(chan struct{}),
 	}
-	modeler := scheduler.NewSimpleModeler(&cache.StoreToPodLister{c.PodQueue}, c.ScheduledPodLister)
-	c.modeler = modeler
-	c.PodLister = modeler.PodLister()
+	c.schedulerCache = schedulercache.New(defaultAssumedPodTTL, c.StopEverything)
+	c.PodLister = &cachePodLister{queue: c.PodQueue, cache: c.schedulerCache}
+	c.equivalenceCache = newEquivalenceCache()
+	c.schedulingLatency = newSchedulingLatencyTracker()
+	// A pod parked as unschedulable and then deleted (e.g. a routine
+	// scale-down) never reaches the binder's Finish call; forget its
+	// latency-tracker entry here so started doesn't grow unbounded.
+	c.PodQueue.onDelete = func(key string) {
+		c.schedulingLatency.Forget(key)
+	}
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(client.Events(""))
+	eventBroadcaster.StartLogging(glog.Infof)
+	c.Recorder = eventBroadcaster.NewRecorder(api.EventSource{Component: "scheduler"})
 
-	// On add/delete to the scheduled pods, remove from the assumed pods.
-	// We construct this here instead of in CreateFromKeys because
+	// On add/update/delete to the scheduled pods, keep the scheduler cache
+	// in sync. We construct this here instead of in CreateFromKeys because
 	// ScheduledPodLister is something we provide to plug in functions that
 	// they may need to call.
 	c.ScheduledPodLister.Store, c.scheduledPodPopulator = framework.NewInformer(
@@ -84,16 +129,43 @@ func NewConfigFactory(client *client.Client) *ConfigFactory {
 		framework.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
 				if pod, ok := obj.(*api.Pod); ok {
-					c.modeler.ForgetPod(pod)
+					if err := c.schedulerCache.AddPod(pod); err != nil {
+						glog.Errorf("scheduler cache AddPod failed: %v", err)
+					}
+					// The node's pod set changed, so any cached predicate
+					// result for it may now be stale.
+					c.equivalenceCache.InvalidateNode(pod.Spec.Host)
+				}
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldPod, ok1 := oldObj.(*api.Pod)
+				newPod, ok2 := newObj.(*api.Pod)
+				if ok1 && ok2 {
+					if err := c.schedulerCache.UpdatePod(oldPod, newPod); err != nil {
+						glog.Errorf("scheduler cache UpdatePod failed: %v", err)
+					}
+					c.equivalenceCache.InvalidateNode(newPod.Spec.Host)
 				}
 			},
 			DeleteFunc: func(obj interface{}) {
+				var pod *api.Pod
 				switch t := obj.(type) {
 				case *api.Pod:
-					c.modeler.ForgetPod(t)
+					pod = t
 				case cache.DeletedFinalStateUnknown:
-					c.modeler.ForgetPodByKey(t.Key)
+					if p, ok := t.Obj.(*api.Pod); ok {
+						pod = p
+					}
+				}
+				if pod != nil {
+					if err := c.schedulerCache.RemovePod(pod); err != nil {
+						glog.Errorf("scheduler cache RemovePod failed: %v", err)
+					}
+					c.equivalenceCache.InvalidateNode(pod.Spec.Host)
 				}
+				// A scheduled pod going away may free up room (or a node
+				// selector match) for pods parked as unschedulable.
+				c.PodQueue.MoveAllToActiveQueue()
 			},
 		},
 	)
@@ -101,6 +173,51 @@ func NewConfigFactory(client *client.Client) *ConfigFactory {
 	return c
 }
 
+// defaultAssumedPodTTL is how long the scheduler cache keeps an assumed
+// pod around before forgetting it if it never shows up in the
+// scheduled-pod reflector.
+const defaultAssumedPodTTL = 30 * time.Second
+
+// cachePodLister presents the union of pods waiting to be scheduled and
+// pods the scheduler cache already knows about (scheduled or assumed) as a
+// single algorithm.PodLister, matching what predicates/priorities expect
+// to see when spreading pods across a cluster.
+type cachePodLister struct {
+	queue *PriorityPodQueue
+	cache schedulercache.Cache
+}
+
+// cacheNodeInfo adapts schedulercache.Cache to whatever thin NodeInfo
+// lookup interface PluginFactoryArgs expects of plugins that only need
+// the node object (e.g. to read its labels or capacity), letting them
+// share the same cache the predicates use for aggregated pod state.
+type cacheNodeInfo struct {
+	cache schedulercache.Cache
+}
+
+func (c *cacheNodeInfo) GetNodeInfo(nodeName string) (*api.Node, error) {
+	info := c.cache.GetNodeInfo(nodeName)
+	if info == nil || info.Node() == nil {
+		return nil, fmt.Errorf("node '%v' not found", nodeName)
+	}
+	return info.Node(), nil
+}
+
+func (c *cachePodLister) List(selector labels.Selector) (pods []api.Pod, err error) {
+	for _, obj := range c.queue.List() {
+		pod := obj.(*api.Pod)
+		if selector.Matches(labels.Set(pod.Labels)) {
+			pods = append(pods, *pod)
+		}
+	}
+	for _, pod := range c.cache.List() {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			pods = append(pods, *pod)
+		}
+	}
+	return pods, nil
+}
+
 // Create creates a scheduler with the default algorithm provider.
 func (f *ConfigFactory) Create() (*scheduler.Config, error) {
 	return f.CreateFromProvider(DefaultProvider)
@@ -114,7 +231,7 @@ func (f *ConfigFactory) CreateFromProvider(providerName string) (*scheduler.Conf
 		return nil, err
 	}
 
-	return f.CreateFromKeys(provider.FitPredicateKeys, provider.PriorityFunctionKeys)
+	return f.CreateFromKeys(provider.FitPredicateKeys, provider.PriorityFunctionKeys, nil)
 }
 
 // Creates a scheduler from the configuration file
@@ -138,17 +255,34 @@ func (f *ConfigFactory) CreateFromConfig(policy schedulerapi.Policy) (*scheduler
 		priorityKeys.Insert(RegisterCustomPriorityFunction(priority))
 	}
 
-	return f.CreateFromKeys(predicateKeys, priorityKeys)
+	extenders, err := buildExtenders(policy.ExtenderConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.CreateFromKeys(predicateKeys, priorityKeys, extenders)
 }
 
-// Creates a scheduler from a set of registered fit predicate keys and priority keys.
-func (f *ConfigFactory) CreateFromKeys(predicateKeys, priorityKeys util.StringSet) (*scheduler.Config, error) {
+// Creates a scheduler from a set of registered fit predicate keys and
+// priority keys, plus any configured extenders.
+func (f *ConfigFactory) CreateFromKeys(predicateKeys, priorityKeys util.StringSet, extenders []SchedulerExtender) (*scheduler.Config, error) {
 	glog.V(2).Infof("creating scheduler with fit predicates '%v' and priority functions '%v", predicateKeys, priorityKeys)
+
+	if f.LeaderElection != nil {
+		glog.Infof("waiting to acquire leader lease %v/%v before starting scheduler", f.LeaderElection.Namespace, f.LeaderElection.Name)
+		if err := f.acquireLeaderElection(); err != nil {
+			return nil, err
+		}
+	}
+
 	pluginArgs := PluginFactoryArgs{
 		PodLister:     f.PodLister,
 		ServiceLister: f.ServiceLister,
 		NodeLister:    f.NodeLister,
-		NodeInfo:      f.NodeLister,
+		// NodeInfo is now backed by the scheduler cache so predicates run
+		// against an O(1) precomputed per-node snapshot instead of
+		// re-walking the PodLister on every scheduling attempt.
+		NodeInfo: &cacheNodeInfo{f.schedulerCache},
 	}
 	predicateFuncs, err := getFitPredicateFunctions(predicateKeys, pluginArgs)
 	if err != nil {
@@ -166,9 +300,58 @@ func (f *ConfigFactory) CreateFromKeys(predicateKeys, priorityKeys util.StringSe
 	// Begin populating scheduled pods.
 	go f.scheduledPodPopulator.Run(f.StopEverything)
 
-	// Watch minions.
-	// Minions may be listed frequently, so provide a local up-to-date cache.
-	cache.NewReflector(f.createMinionLW(), &api.Node{}, f.NodeLister.Store, 0).RunUntil(f.StopEverything)
+	// Watch minions. Minions may be listed frequently, so provide a local
+	// up-to-date cache, and keep the scheduler cache's per-node snapshots
+	// in sync. A node being added or updated may make room (or a new
+	// match) for pods parked in the unschedulable queue, so flush them
+	// back to the active queue on either event.
+	nodeStore, nodePopulator := framework.NewInformer(
+		f.createMinionLW(),
+		&api.Node{},
+		0,
+		framework.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				if node, ok := obj.(*api.Node); ok {
+					if err := f.schedulerCache.AddNode(node); err != nil {
+						glog.Errorf("scheduler cache AddNode failed: %v", err)
+					}
+				}
+				f.PodQueue.MoveAllToActiveQueue()
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldNode, ok1 := oldObj.(*api.Node)
+				newNode, ok2 := newObj.(*api.Node)
+				if ok1 && ok2 {
+					if err := f.schedulerCache.UpdateNode(oldNode, newNode); err != nil {
+						glog.Errorf("scheduler cache UpdateNode failed: %v", err)
+					}
+					// The node object itself changed (labels, taints,
+					// capacity, ...), so predicate results cached against
+					// it may no longer hold.
+					f.equivalenceCache.InvalidateNode(newNode.Name)
+				}
+				f.PodQueue.MoveAllToActiveQueue()
+			},
+			DeleteFunc: func(obj interface{}) {
+				var node *api.Node
+				switch t := obj.(type) {
+				case *api.Node:
+					node = t
+				case cache.DeletedFinalStateUnknown:
+					if n, ok := t.Obj.(*api.Node); ok {
+						node = n
+					}
+				}
+				if node != nil {
+					if err := f.schedulerCache.RemoveNode(node); err != nil {
+						glog.Errorf("scheduler cache RemoveNode failed: %v", err)
+					}
+				}
+			},
+		},
+	)
+	f.NodeLister.Store = nodeStore
+	go nodePopulator.Run(f.StopEverything)
 
 	// Watch and cache all service objects. Scheduler needs to find all pods
 	// created by the same service, so that it can spread them correctly.
@@ -177,31 +360,84 @@ func (f *ConfigFactory) CreateFromKeys(predicateKeys, priorityKeys util.StringSe
 
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	algo := algorithm.NewGenericScheduler(predicateFuncs, priorityConfigs, f.PodLister, r)
+	algo := newParallelGenericScheduler(predicateFuncs, priorityConfigs, f.PodLister, r, f.ParallelWorkers, f.equivalenceCache, extenders, f.schedulerCache)
 
-	podBackoff := podBackoff{
-		perPodBackoff: map[string]*backoffEntry{},
-		clock:         realClock{},
+	go observeQueueDepths(f.PodQueue, f.StopEverything)
 
-		defaultDuration: 1 * time.Second,
-		maxDuration:     60 * time.Second,
-	}
+	// PodQueue.Pop returns nil once Close is called; wire that to
+	// StopEverything so the blocked Pop goroutine doesn't leak at shutdown.
+	go func() {
+		<-f.StopEverything
+		f.PodQueue.Close()
+	}()
 
 	return &scheduler.Config{
-		Modeler:      f.modeler,
 		MinionLister: f.NodeLister,
 		Algorithm:    algo,
-		Binder:       &binder{f.Client},
+		Binder:       &binder{f.Client, f.schedulerCache, f.Recorder, f.schedulingLatency, f.equivalenceCache},
 		NextPod: func() *api.Pod {
-			pod := f.PodQueue.Pop().(*api.Pod)
+			pod := f.PodQueue.Pop()
+			if pod == nil {
+				// Queue was closed (StopEverything fired); nothing to schedule.
+				return nil
+			}
+			if key, err := podKey(pod); err == nil {
+				f.schedulingLatency.Start(key)
+			}
 			glog.V(2).Infof("About to try and schedule pod %v", pod.Name)
 			return pod
 		},
-		Error:          f.makeDefaultErrorFunc(&podBackoff, f.PodQueue),
+		Error:          f.makeDefaultErrorFunc(f.PodQueue),
 		StopEverything: f.StopEverything,
 	}, nil
 }
 
+// Run launches f.ParallelWorkers goroutines that each independently pop a
+// pod via config.NextPod, schedule it, and bind it, so that independent
+// pods - which the PriorityPodQueue already hands out one at a time and
+// safely for concurrent callers - can be scheduled against distinct nodes
+// at the same time instead of one pod at a time. It blocks until every
+// worker's NextPod call returns nil, which happens once config.StopEverything
+// fires and PodQueue.Close unblocks them.
+func (f *ConfigFactory) Run(config *scheduler.Config) {
+	workers := f.ParallelWorkers
+	if workers <= 0 {
+		workers = defaultParallelWorkers
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				pod := config.NextPod()
+				if pod == nil {
+					return
+				}
+				f.scheduleOne(config, pod)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// scheduleOne runs the algorithm and binder for a single pod, reporting the
+// pod as unschedulable via config.Error if either step fails.
+func (f *ConfigFactory) scheduleOne(config *scheduler.Config, pod *api.Pod) {
+	host, err := config.Algorithm.Schedule(*pod, config.MinionLister)
+	if err != nil {
+		config.Error(pod, err)
+		return
+	}
+	binding := &api.Binding{
+		ObjectMeta: api.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+		Target:     api.ObjectReference{Kind: "Node", Name: host},
+	}
+	if err := config.Binder.Bind(binding); err != nil {
+		config.Error(pod, err)
+	}
+}
+
 // Returns a cache.ListWatch that finds all pods that need to be
 // scheduled.
 func (factory *ConfigFactory) createUnassignedPodLW() *cache.ListWatch {
@@ -267,28 +503,20 @@ func (factory *ConfigFactory) createServiceLW() *cache.ListWatch {
 	return cache.NewListWatchFromClient(factory.Client, "services", api.NamespaceAll, parseSelectorOrDie(""))
 }
 
-func (factory *ConfigFactory) makeDefaultErrorFunc(backoff *podBackoff, podQueue *cache.FIFO) func(pod *api.Pod, err error) {
+// makeDefaultErrorFunc parks a pod that failed to schedule in the
+// unschedulable sub-queue of podQueue instead of retrying it on a fixed
+// backoff sleep. It only comes back onto the active queue once the cluster
+// state actually changes (see PriorityPodQueue.MoveAllToActiveQueue).
+// Besides the log line, it also records a FailedScheduling event on the
+// pod, so the failure (and why) shows up in `kubectl describe pod`
+// without anyone having to go looking at the scheduler's own logs.
+func (factory *ConfigFactory) makeDefaultErrorFunc(podQueue *PriorityPodQueue) func(pod *api.Pod, err error) {
 	return func(pod *api.Pod, err error) {
-		glog.Errorf("Error scheduling %v %v: %v; retrying", pod.Namespace, pod.Name, err)
-		backoff.gc()
-		// Retry asynchronously.
-		// Note that this is extremely rudimentary and we need a more real error handling path.
-		go func() {
-			defer util.HandleCrash()
-			podID := pod.Name
-			podNamespace := pod.Namespace
-			backoff.wait(podID)
-			// Get the pod again; it may have changed/been scheduled already.
-			pod = &api.Pod{}
-			err := factory.Client.Get().Namespace(podNamespace).Resource("pods").Name(podID).Do().Into(pod)
-			if err != nil {
-				glog.Errorf("Error getting pod %v for retry: %v; abandoning", podID, err)
-				return
-			}
-			if pod.Spec.Host == "" {
-				podQueue.Add(pod)
-			}
-		}()
+		glog.Errorf("Error scheduling %v %v: %v; parking as unschedulable", pod.Namespace, pod.Name, err)
+		if factory.Recorder != nil {
+			factory.Recorder.Eventf(pod, "FailedScheduling", "%v", err)
+		}
+		podQueue.AddUnschedulable(pod)
 	}
 }
 
@@ -312,74 +540,60 @@ func (ne *nodeEnumerator) Get(index int) interface{} {
 
 type binder struct {
 	*client.Client
+	schedulerCache schedulercache.Cache
+	recorder       record.EventRecorder
+	latency        *schedulingLatencyTracker
+	equivCache     *equivalenceCache
 }
 
-// Bind just does a POST binding RPC.
+// Bind does a POST binding RPC, assuming the pod onto its target node in
+// the scheduler cache for the duration of the call so that a second pod
+// racing to be scheduled immediately afterwards sees accurate node state
+// rather than waiting for this binding to round-trip through the
+// scheduled-pod reflector. On success it records a Scheduled event and
+// reports binding and end-to-end scheduling latency to Prometheus.
 func (b *binder) Bind(binding *api.Binding) error {
 	glog.V(2).Infof("Attempting to bind %v to %v", binding.Name, binding.Target.Name)
 	ctx := api.WithNamespace(api.NewContext(), binding.Namespace)
-	return b.Post().Namespace(api.NamespaceValue(ctx)).Resource("bindings").Body(binding).Do().Error()
-	// TODO: use Pods interface for binding once clusters are upgraded
-	// return b.Pods(binding.Namespace).Bind(binding)
-}
-
-type clock interface {
-	Now() time.Time
-}
-
-type realClock struct{}
-
-func (realClock) Now() time.Time {
-	return time.Now()
-}
 
-type backoffEntry struct {
-	backoff    time.Duration
-	lastUpdate time.Time
-}
-
-type podBackoff struct {
-	perPodBackoff   map[string]*backoffEntry
-	lock            sync.Mutex
-	clock           clock
-	defaultDuration time.Duration
-	maxDuration     time.Duration
-}
-
-func (p *podBackoff) getEntry(podID string) *backoffEntry {
-	p.lock.Lock()
-	defer p.lock.Unlock()
-	entry, ok := p.perPodBackoff[podID]
-	if !ok {
-		entry = &backoffEntry{backoff: p.defaultDuration}
-		p.perPodBackoff[podID] = entry
+	pod := &api.Pod{}
+	if err := b.Get().Namespace(binding.Namespace).Resource("pods").Name(binding.Name).Do().Into(pod); err != nil {
+		return err
 	}
-	entry.lastUpdate = p.clock.Now()
-	return entry
-}
-
-func (p *podBackoff) getBackoff(podID string) time.Duration {
-	entry := p.getEntry(podID)
-	duration := entry.backoff
-	entry.backoff *= 2
-	if entry.backoff > p.maxDuration {
-		entry.backoff = p.maxDuration
+	pod.Spec.Host = binding.Target.Name
+	if err := b.schedulerCache.AssumePod(pod); err != nil {
+		glog.Errorf("scheduler cache AssumePod failed: %v", err)
+	}
+	// The node's occupancy just changed; bump its equivalence-cache
+	// generation immediately instead of waiting for the delayed
+	// scheduled-pod watch event, so a concurrent Schedule call for
+	// another pod can't reuse a "fits" result computed before this
+	// assumption.
+	b.equivCache.InvalidateNode(pod.Spec.Host)
+
+	bindStart := time.Now()
+	err := b.Post().Namespace(api.NamespaceValue(ctx)).Resource("bindings").Body(binding).Do().Error()
+	bindingLatency.Observe(float64(time.Since(bindStart).Nanoseconds() / int64(time.Microsecond)))
+	if err != nil {
+		if ferr := b.schedulerCache.ForgetPod(pod); ferr != nil {
+			glog.Errorf("scheduler cache ForgetPod failed: %v", ferr)
+		}
+		b.equivCache.InvalidateNode(pod.Spec.Host)
+		return err
+	}
+	if err := b.schedulerCache.FinishBinding(pod); err != nil {
+		glog.Errorf("scheduler cache FinishBinding failed: %v", err)
 	}
-	glog.V(4).Infof("Backing off %s for pod %s", duration.String(), podID)
-	return duration
-}
-
-func (p *podBackoff) wait(podID string) {
-	time.Sleep(p.getBackoff(podID))
-}
 
-func (p *podBackoff) gc() {
-	p.lock.Lock()
-	defer p.lock.Unlock()
-	now := p.clock.Now()
-	for podID, entry := range p.perPodBackoff {
-		if now.Sub(entry.lastUpdate) > p.maxDuration {
-			delete(p.perPodBackoff, podID)
+	if b.recorder != nil {
+		b.recorder.Eventf(pod, "Scheduled", "Successfully assigned %v to %v", pod.Name, binding.Target.Name)
+	}
+	if key, err := podKey(pod); err == nil && b.latency != nil {
+		if elapsed, ok := b.latency.Finish(key); ok {
+			e2eSchedulingLatency.Observe(float64(elapsed.Nanoseconds() / int64(time.Microsecond)))
 		}
 	}
+	return nil
+	// TODO: use Pods interface for binding once clusters are upgraded
+	// return b.Pods(binding.Namespace).Bind(binding)
 }
@@ -0,0 +1,224 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors"
+
+	"github.com/golang/glog"
+)
+
+// leaderElectionRecordAnnotationKey mirrors the well-known annotation used
+// across the control plane to record who currently holds a lease, so that
+// other tools (kubectl, dashboards) inspecting the same Endpoints object
+// recognize it the same way.
+const leaderElectionRecordAnnotationKey = "control-plane.alpha.kubernetes.io/leader"
+
+// LeaderElectionConfig configures ConfigFactory to run multiple scheduler
+// replicas for HA, with only the lease holder actually scheduling pods at
+// any given time. Leave it nil (the default) to run without leader
+// election, as a single active scheduler.
+type LeaderElectionConfig struct {
+	// Namespace/Name identify the Endpoints object used as the lease.
+	Namespace string
+	Name      string
+	// Identity uniquely names this process as a candidate; defaults to
+	// "<hostname>_<pid>" if empty.
+	Identity string
+
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// DefaultLeaderElectionConfig returns sane defaults for the given lease
+// resource, suitable for most HA scheduler deployments.
+func DefaultLeaderElectionConfig(namespace, name string) *LeaderElectionConfig {
+	return &LeaderElectionConfig{
+		Namespace:     namespace,
+		Name:          name,
+		LeaseDuration: 15 * time.Second,
+		RenewDeadline: 10 * time.Second,
+		RetryPeriod:   2 * time.Second,
+	}
+}
+
+func (c *LeaderElectionConfig) identity() string {
+	if c.Identity != "" {
+		return c.Identity
+	}
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s_%d", host, os.Getpid())
+}
+
+type leaderElectionRecord struct {
+	HolderIdentity       string    `json:"holderIdentity"`
+	LeaseDurationSeconds int       `json:"leaseDurationSeconds"`
+	AcquireTime          time.Time `json:"acquireTime"`
+	RenewTime            time.Time `json:"renewTime"`
+}
+
+// acquireLeaderElection blocks, retrying every f.LeaderElection.RetryPeriod,
+// until this process acquires (or renews into) the configured lease. Once
+// acquired it starts a background goroutine that keeps renewing the lease
+// and closes f.StopEverything once a renewal hasn't landed successfully
+// for RenewDeadline, so a replica that loses its lease stops scheduling
+// promptly instead of running split-brain alongside the new leader.
+func (f *ConfigFactory) acquireLeaderElection() error {
+	cfg := f.LeaderElection
+	identity := cfg.identity()
+
+	for {
+		acquired, err := f.tryAcquireOrRenewLease(identity)
+		if err != nil {
+			glog.Errorf("error contacting apiserver for leader election: %v", err)
+		} else if acquired {
+			glog.Infof("acquired leader lease %v/%v as %v", cfg.Namespace, cfg.Name, identity)
+			go f.renewLeaderElectionForever(identity)
+			return nil
+		}
+		select {
+		case <-f.StopEverything:
+			return fmt.Errorf("stopped before acquiring leader lease %v/%v", cfg.Namespace, cfg.Name)
+		case <-time.After(cfg.RetryPeriod):
+		}
+	}
+}
+
+// renewLeaderElectionForever renews the lease every RetryPeriod until
+// either f.StopEverything is closed, another identity outright acquires
+// the lease, or renewal hasn't landed successfully for RenewDeadline, at
+// which point it closes f.StopEverything itself so the rest of the
+// scheduler shuts down. A single transient apiserver error on a renewal
+// attempt is not treated as losing the lease; it only counts against
+// RenewDeadline like any other failed-to-land renewal.
+func (f *ConfigFactory) renewLeaderElectionForever(identity string) {
+	cfg := f.LeaderElection
+	ticker := time.NewTicker(cfg.RetryPeriod)
+	defer ticker.Stop()
+	lastRenew := time.Now()
+	for {
+		select {
+		case <-f.StopEverything:
+			return
+		case <-ticker.C:
+			held, err := f.tryAcquireOrRenewLease(identity)
+			if err == nil && held {
+				lastRenew = time.Now()
+				continue
+			}
+			if err == nil && !held {
+				glog.Errorf("lost leader lease %v/%v to another holder", cfg.Namespace, cfg.Name)
+				close(f.StopEverything)
+				return
+			}
+			glog.Errorf("error renewing leader lease %v/%v: %v", cfg.Namespace, cfg.Name, err)
+			if time.Since(lastRenew) > cfg.RenewDeadline {
+				glog.Errorf("failed to renew leader lease %v/%v within %v, giving up leadership", cfg.Namespace, cfg.Name, cfg.RenewDeadline)
+				close(f.StopEverything)
+				return
+			}
+		}
+	}
+}
+
+// tryAcquireOrRenewLease attempts to become (or remain) the holder of the
+// configured lease, returning true if identity now holds it.
+func (f *ConfigFactory) tryAcquireOrRenewLease(identity string) (bool, error) {
+	cfg := f.LeaderElection
+	now := time.Now()
+
+	endpoints := &api.Endpoints{}
+	err := f.Client.Get().Namespace(cfg.Namespace).Resource("endpoints").Name(cfg.Name).Do().Into(endpoints)
+	if err != nil && !errors.IsNotFound(err) {
+		// A transient apiserver error (timeout, 5xx, auth failure) is not
+		// the same as the lease object not existing yet; don't fall
+		// through to creating it, since it's probably still there.
+		return false, err
+	}
+	notFound := errors.IsNotFound(err)
+
+	record := leaderElectionRecord{
+		HolderIdentity:       identity,
+		LeaseDurationSeconds: int(cfg.LeaseDuration / time.Second),
+		AcquireTime:          now,
+		RenewTime:            now,
+	}
+
+	if notFound {
+		endpoints = &api.Endpoints{
+			ObjectMeta: api.ObjectMeta{
+				Namespace:   cfg.Namespace,
+				Name:        cfg.Name,
+				Annotations: map[string]string{},
+			},
+		}
+		if err := setLeaderElectionRecord(endpoints, record); err != nil {
+			return false, err
+		}
+		createErr := f.Client.Post().Namespace(cfg.Namespace).Resource("endpoints").Body(endpoints).Do().Error()
+		return createErr == nil, createErr
+	}
+
+	existing, err := getLeaderElectionRecord(endpoints)
+	if err != nil {
+		return false, err
+	}
+	if existing.HolderIdentity != "" && existing.HolderIdentity != identity &&
+		now.Sub(existing.RenewTime) < cfg.LeaseDuration {
+		// Someone else holds an unexpired lease.
+		return false, nil
+	}
+	if existing.HolderIdentity == identity {
+		// Renewing our own lease keeps the original acquire time.
+		record.AcquireTime = existing.AcquireTime
+	}
+
+	if err := setLeaderElectionRecord(endpoints, record); err != nil {
+		return false, err
+	}
+	updateErr := f.Client.Put().Namespace(cfg.Namespace).Resource("endpoints").Name(cfg.Name).Body(endpoints).Do().Error()
+	return updateErr == nil, updateErr
+}
+
+func setLeaderElectionRecord(endpoints *api.Endpoints, record leaderElectionRecord) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if endpoints.Annotations == nil {
+		endpoints.Annotations = map[string]string{}
+	}
+	endpoints.Annotations[leaderElectionRecordAnnotationKey] = string(encoded)
+	return nil
+}
+
+func getLeaderElectionRecord(endpoints *api.Endpoints) (leaderElectionRecord, error) {
+	var record leaderElectionRecord
+	raw, ok := endpoints.Annotations[leaderElectionRecordAnnotationKey]
+	if !ok {
+		return record, nil
+	}
+	err := json.Unmarshal([]byte(raw), &record)
+	return record, err
+}
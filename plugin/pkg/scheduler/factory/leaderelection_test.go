@@ -0,0 +1,71 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+)
+
+func TestLeaderElectionConfigIdentityDefaultsToHostPid(t *testing.T) {
+	cfg := &LeaderElectionConfig{}
+	if got := cfg.identity(); got == "" {
+		t.Fatalf("identity() with no Identity set returned empty string")
+	}
+}
+
+func TestLeaderElectionConfigIdentityHonorsOverride(t *testing.T) {
+	cfg := &LeaderElectionConfig{Identity: "scheduler-1"}
+	if got := cfg.identity(); got != "scheduler-1" {
+		t.Fatalf("identity() = %q, want %q", got, "scheduler-1")
+	}
+}
+
+func TestSetAndGetLeaderElectionRecordRoundTrips(t *testing.T) {
+	endpoints := &api.Endpoints{}
+	record := leaderElectionRecord{
+		HolderIdentity:       "scheduler-1",
+		LeaseDurationSeconds: 15,
+		AcquireTime:          time.Unix(1000, 0).UTC(),
+		RenewTime:            time.Unix(2000, 0).UTC(),
+	}
+
+	if err := setLeaderElectionRecord(endpoints, record); err != nil {
+		t.Fatalf("setLeaderElectionRecord: %v", err)
+	}
+
+	got, err := getLeaderElectionRecord(endpoints)
+	if err != nil {
+		t.Fatalf("getLeaderElectionRecord: %v", err)
+	}
+	if got != record {
+		t.Fatalf("getLeaderElectionRecord = %+v, want %+v", got, record)
+	}
+}
+
+func TestGetLeaderElectionRecordMissingAnnotation(t *testing.T) {
+	endpoints := &api.Endpoints{}
+	record, err := getLeaderElectionRecord(endpoints)
+	if err != nil {
+		t.Fatalf("getLeaderElectionRecord on an endpoints with no annotation: %v", err)
+	}
+	if record != (leaderElectionRecord{}) {
+		t.Fatalf("getLeaderElectionRecord = %+v, want zero value", record)
+	}
+}
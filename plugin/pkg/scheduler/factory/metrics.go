@@ -0,0 +1,127 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsSubsystem = "scheduler"
+
+var (
+	e2eSchedulingLatency = prometheus.NewSummary(prometheus.SummaryOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "e2e_scheduling_latency_microseconds",
+		Help:      "Latency in microseconds between a pod being popped off the scheduling queue for its latest attempt and being successfully bound to a node.",
+	})
+
+	bindingLatency = prometheus.NewSummary(prometheus.SummaryOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "binding_latency_microseconds",
+		Help:      "Latency in microseconds of the binder's POST to the apiserver.",
+	})
+
+	podQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "pod_queue_depth",
+		Help:      "Number of pods currently waiting in the active scheduling queue.",
+	})
+
+	unschedulablePods = prometheus.NewGauge(prometheus.GaugeOpts{
+		Subsystem: metricsSubsystem,
+		Name:      "unschedulable_pods",
+		Help:      "Number of pods currently parked as unschedulable, waiting for cluster state to change.",
+	})
+
+	predicateEvaluationLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: metricsSubsystem,
+			Name:      "predicate_evaluation_seconds",
+			Help:      "Time in seconds a single fit predicate takes to evaluate a pod against a node.",
+		},
+		[]string{"predicate"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(e2eSchedulingLatency)
+	prometheus.MustRegister(bindingLatency)
+	prometheus.MustRegister(podQueueDepth)
+	prometheus.MustRegister(unschedulablePods)
+	prometheus.MustRegister(predicateEvaluationLatency)
+}
+
+// observeQueueDepths periodically publishes the active/unschedulable queue
+// lengths as gauges until stop is closed.
+func observeQueueDepths(queue *PriorityPodQueue, stop <-chan struct{}) {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			active, unschedulable := queue.Lengths()
+			podQueueDepth.Set(float64(active))
+			unschedulablePods.Set(float64(unschedulable))
+		case <-stop:
+			return
+		}
+	}
+}
+
+// schedulingLatencyTracker records when each pod was last popped off the
+// scheduling queue for an attempt so the binder can report end-to-end
+// scheduling latency once it is successfully bound.
+type schedulingLatencyTracker struct {
+	mu      sync.Mutex
+	started map[string]time.Time
+}
+
+func newSchedulingLatencyTracker() *schedulingLatencyTracker {
+	return &schedulingLatencyTracker{started: map[string]time.Time{}}
+}
+
+func (t *schedulingLatencyTracker) Start(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.started[key] = time.Now()
+}
+
+// Finish returns the elapsed time since Start(key) was called, if it was,
+// and forgets key either way.
+func (t *schedulingLatencyTracker) Finish(key string) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start, ok := t.started[key]
+	if !ok {
+		return 0, false
+	}
+	delete(t.started, key)
+	return time.Since(start), true
+}
+
+// Forget discards key's start time without reporting latency for it. Used
+// when a pod is removed from the queue/cache (e.g. deleted while still
+// unschedulable) and will never reach Finish, so started doesn't grow
+// without bound.
+func (t *schedulingLatencyTracker) Forget(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.started, key)
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	algorithm "github.com/GoogleCloudPlatform/kubernetes/pkg/scheduler"
+	"github.com/GoogleCloudPlatform/kubernetes/plugin/pkg/scheduler/schedulercache"
+
+	"github.com/golang/glog"
+)
+
+// defaultParallelWorkers is how many goroutines evaluate fit predicates
+// concurrently across nodes when ConfigFactory.ParallelWorkers is unset.
+const defaultParallelWorkers = 16
+
+// FitError is returned by parallelGenericScheduler.Schedule when a pod
+// doesn't fit on any node. It aggregates which predicate rejected the pod
+// and on how many nodes, so the error that reaches the FailedScheduling
+// event (and kubectl describe pod) says why the pod didn't fit instead of
+// just how many nodes were tried.
+type FitError struct {
+	Pod              *api.Pod
+	NumAllNodes      int
+	FailedPredicates map[string]int
+}
+
+func (f *FitError) Error() string {
+	reasons := make([]string, 0, len(f.FailedPredicates))
+	for name, count := range f.FailedPredicates {
+		reasons = append(reasons, fmt.Sprintf("%v (%d)", name, count))
+	}
+	sort.Strings(reasons)
+	return fmt.Sprintf("pod %v/%v does not fit on any of %d nodes: %v", f.Pod.Namespace, f.Pod.Name, f.NumAllNodes, strings.Join(reasons, ", "))
+}
+
+// parallelGenericScheduler is a algorithm.Scheduler that fans predicate
+// evaluation for a single pod out across many nodes concurrently instead
+// of walking the node list one node at a time. The final priority scoring
+// and node selection still happen on the calling goroutine. ConfigFactory.Run
+// additionally calls Schedule from multiple worker goroutines at once, one
+// per pod, so distinct pods are scheduled against distinct nodes
+// concurrently; binder.Bind's scheduler-cache assume step keeps those
+// workers from racing each other onto the same node.
+type parallelGenericScheduler struct {
+	predicates map[string]algorithm.FitPredicate
+	priorities []algorithm.PriorityConfig
+	pods       algorithm.PodLister
+	random     *rand.Rand
+	workers    int
+
+	// nodeCache supplies the per-node pod snapshot predicates are
+	// evaluated against. When set, it replaces the O(all pods) PodLister
+	// walk with an O(1) lookup of the node's already-aggregated pod list.
+	nodeCache schedulercache.Cache
+
+	// equivCache memoizes predicate results per (predicate, equivalence
+	// class, node) so that large ReplicaSets/DaemonSets whose pods share
+	// an equivalence class don't each pay the full predicate cost. May be
+	// nil, in which case every predicate runs uncached.
+	equivCache *equivalenceCache
+
+	// extenders are consulted, in order, after the in-process predicates
+	// and priorities: each gets a chance to further filter the surviving
+	// nodes and to contribute its own weighted score.
+	extenders []SchedulerExtender
+}
+
+// newParallelGenericScheduler builds a parallelGenericScheduler. workers
+// <= 0 falls back to defaultParallelWorkers.
+func newParallelGenericScheduler(predicates map[string]algorithm.FitPredicate, priorities []algorithm.PriorityConfig, pods algorithm.PodLister, random *rand.Rand, workers int, equivCache *equivalenceCache, extenders []SchedulerExtender, nodeCache schedulercache.Cache) algorithm.Scheduler {
+	if workers <= 0 {
+		workers = defaultParallelWorkers
+	}
+	return &parallelGenericScheduler{
+		predicates: predicates,
+		priorities: priorities,
+		pods:       pods,
+		random:     random,
+		workers:    workers,
+		equivCache: equivCache,
+		extenders:  extenders,
+		nodeCache:  nodeCache,
+	}
+}
+
+// existingPodsForNode returns the pods already (or tentatively, via
+// assumption) occupying node, consulting the scheduler cache's
+// precomputed NodeInfo instead of filtering a full cluster-wide pod list.
+// Falls back to an empty slice if the node isn't in the cache yet, which
+// only makes predicates under-count a brand new node's occupancy rather
+// than fail the scheduling attempt outright.
+func (g *parallelGenericScheduler) existingPodsForNode(node string) []api.Pod {
+	if g.nodeCache == nil {
+		return nil
+	}
+	info := g.nodeCache.GetNodeInfo(node)
+	pods := info.Pods()
+	if len(pods) == 0 {
+		return nil
+	}
+	result := make([]api.Pod, len(pods))
+	for i, pod := range pods {
+		result[i] = *pod
+	}
+	return result
+}
+
+// fitsPredicate runs a single named predicate for pod against node,
+// consulting the equivalence cache first when one is configured.
+func (g *parallelGenericScheduler) fitsPredicate(name string, predicate algorithm.FitPredicate, pod *api.Pod, existingPods []api.Pod, node, equivClass string) (bool, error) {
+	if g.equivCache != nil {
+		if fits, ok := g.equivCache.Lookup(name, equivClass, node); ok {
+			return fits, nil
+		}
+	}
+	start := time.Now()
+	fits, err := predicate(pod, existingPods, node)
+	predicateEvaluationLatency.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	if err == nil && g.equivCache != nil {
+		g.equivCache.Update(name, equivClass, node, fits)
+	}
+	return fits, err
+}
+
+// nodesByName returns the subset of nodes whose name appears in names.
+func nodesByName(nodes []api.Node, names []string) []api.Node {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var result []api.Node
+	for _, node := range nodes {
+		if wanted[node.Name] {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+func (g *parallelGenericScheduler) Schedule(pod api.Pod, minionLister algorithm.MinionLister) (string, error) {
+	nodes, err := minionLister.List()
+	if err != nil {
+		return "", err
+	}
+	if len(nodes.Items) == 0 {
+		return "", fmt.Errorf("no nodes available to schedule pods")
+	}
+
+	equivClass := ""
+	if g.equivCache != nil {
+		equivClass = equivalenceHash(&pod)
+	}
+
+	fits := make([]string, len(nodes.Items))
+	failedPredicates := make([]string, len(nodes.Items))
+	predicateErrs := make([]error, len(nodes.Items))
+
+	parallelizeUntil(g.workers, len(nodes.Items), func(i int) {
+		node := nodes.Items[i].Name
+		existingPods := g.existingPodsForNode(node)
+		for name, predicate := range g.predicates {
+			ok, err := g.fitsPredicate(name, predicate, &pod, existingPods, node, equivClass)
+			if err != nil {
+				predicateErrs[i] = err
+				return
+			}
+			if !ok {
+				failedPredicates[i] = name
+				return
+			}
+		}
+		fits[i] = node
+	})
+
+	var feasible []string
+	failureCounts := map[string]int{}
+	for i, node := range fits {
+		switch {
+		case node != "":
+			feasible = append(feasible, node)
+		case predicateErrs[i] != nil:
+			glog.Errorf("fit predicate error for node %v: %v", nodes.Items[i].Name, predicateErrs[i])
+			failureCounts[predicateErrs[i].Error()]++
+		case failedPredicates[i] != "":
+			failureCounts[failedPredicates[i]]++
+		}
+	}
+	if len(feasible) == 0 {
+		return "", &FitError{Pod: &pod, NumAllNodes: len(nodes.Items), FailedPredicates: failureCounts}
+	}
+
+	feasibleNodes := nodesByName(nodes.Items, feasible)
+	for _, extender := range g.extenders {
+		filtered, err := extender.Filter(&pod, feasibleNodes)
+		if err != nil {
+			if extender.Ignorable() {
+				glog.Warningf("ignoring extender %v filter error: %v", extender.Name(), err)
+				continue
+			}
+			return "", err
+		}
+		feasibleNodes = filtered
+	}
+	if len(feasibleNodes) == 0 {
+		return "", fmt.Errorf("pod %v/%v does not fit on any of %d nodes after extender filtering", pod.Namespace, pod.Name, len(feasible))
+	}
+	feasible = feasible[:0]
+	for _, node := range feasibleNodes {
+		feasible = append(feasible, node.Name)
+	}
+
+	if len(g.priorities) == 0 && len(g.extenders) == 0 {
+		return feasible[g.random.Intn(len(feasible))], nil
+	}
+
+	scores := make(map[string]int, len(feasible))
+	for _, config := range g.priorities {
+		result, err := config.Function(&pod, g.pods, minionLister)
+		if err != nil {
+			return "", err
+		}
+		for _, hostPriority := range result {
+			scores[hostPriority.Host] += hostPriority.Score * config.Weight
+		}
+	}
+	for _, extender := range g.extenders {
+		result, err := extender.Prioritize(&pod, feasibleNodes)
+		if err != nil {
+			if extender.Ignorable() {
+				glog.Warningf("ignoring extender %v prioritize error: %v", extender.Name(), err)
+				continue
+			}
+			return "", err
+		}
+		for _, hostPriority := range result {
+			scores[hostPriority.Host] += hostPriority.Score * extender.Weight()
+		}
+	}
+
+	best := feasible[0]
+	bestScore := scores[best]
+	for _, node := range feasible[1:] {
+		if score := scores[node]; score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+	return best, nil
+}
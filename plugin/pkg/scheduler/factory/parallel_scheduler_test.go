@@ -0,0 +1,111 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	algorithm "github.com/GoogleCloudPlatform/kubernetes/pkg/scheduler"
+)
+
+type fakeMinionLister struct {
+	nodes []api.Node
+}
+
+func (f fakeMinionLister) List() (api.NodeList, error) {
+	return api.NodeList{Items: f.nodes}, nil
+}
+
+func alwaysFits(pod *api.Pod, existingPods []api.Pod, node string) (bool, error) {
+	return true, nil
+}
+
+func neverFits(pod *api.Pod, existingPods []api.Pod, node string) (bool, error) {
+	return false, nil
+}
+
+func fitsExcept(except string) algorithm.FitPredicate {
+	return func(pod *api.Pod, existingPods []api.Pod, node string) (bool, error) {
+		return node != except, nil
+	}
+}
+
+func newTestScheduler(predicates map[string]algorithm.FitPredicate) *parallelGenericScheduler {
+	return newParallelGenericScheduler(predicates, nil, nil, rand.New(rand.NewSource(1)), 4, nil, nil, nil).(*parallelGenericScheduler)
+}
+
+func TestParallelGenericSchedulerPicksAFittingNode(t *testing.T) {
+	g := newTestScheduler(map[string]algorithm.FitPredicate{"fitsExceptNode2": fitsExcept("node2")})
+	nodes := fakeMinionLister{nodes: []api.Node{
+		{ObjectMeta: api.ObjectMeta{Name: "node1"}},
+		{ObjectMeta: api.ObjectMeta{Name: "node2"}},
+	}}
+
+	host, err := g.Schedule(api.Pod{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "p"}}, nodes)
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if host != "node1" {
+		t.Fatalf("Schedule chose %v, want node1", host)
+	}
+}
+
+func TestParallelGenericSchedulerReturnsFitErrorWithReasons(t *testing.T) {
+	g := newTestScheduler(map[string]algorithm.FitPredicate{"neverFits": neverFits})
+	nodes := fakeMinionLister{nodes: []api.Node{
+		{ObjectMeta: api.ObjectMeta{Name: "node1"}},
+		{ObjectMeta: api.ObjectMeta{Name: "node2"}},
+	}}
+
+	_, err := g.Schedule(api.Pod{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "p"}}, nodes)
+	if err == nil {
+		t.Fatalf("Schedule with an always-failing predicate returned nil error")
+	}
+	fitErr, ok := err.(*FitError)
+	if !ok {
+		t.Fatalf("Schedule error = %T, want *FitError", err)
+	}
+	if fitErr.NumAllNodes != 2 {
+		t.Fatalf("FitError.NumAllNodes = %d, want 2", fitErr.NumAllNodes)
+	}
+	if fitErr.FailedPredicates["neverFits"] != 2 {
+		t.Fatalf("FitError.FailedPredicates[neverFits] = %d, want 2", fitErr.FailedPredicates["neverFits"])
+	}
+}
+
+func TestParallelGenericSchedulerNoNodes(t *testing.T) {
+	g := newTestScheduler(map[string]algorithm.FitPredicate{"alwaysFits": alwaysFits})
+	_, err := g.Schedule(api.Pod{}, fakeMinionLister{})
+	if err == nil {
+		t.Fatalf("Schedule with no nodes returned nil error")
+	}
+}
+
+func TestFitErrorMessageListsFailedPredicates(t *testing.T) {
+	err := &FitError{
+		Pod:              &api.Pod{ObjectMeta: api.ObjectMeta{Namespace: "default", Name: "p"}},
+		NumAllNodes:      3,
+		FailedPredicates: map[string]int{"PodFitsResources": 3},
+	}
+	want := fmt.Sprintf("pod default/p does not fit on any of 3 nodes: %v", "PodFitsResources (3)")
+	if got := err.Error(); got != want {
+		t.Fatalf("FitError.Error() = %q, want %q", got, want)
+	}
+}
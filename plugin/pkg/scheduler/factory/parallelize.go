@@ -0,0 +1,55 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import "sync"
+
+// parallelizeUntil applies doWork to every index in [0, pieces) using up
+// to workers goroutines, and waits for all of them to finish. A workers
+// value <= 1 runs the work sequentially on the calling goroutine.
+func parallelizeUntil(workers, pieces int, doWork func(piece int)) {
+	if pieces == 0 {
+		return
+	}
+	if workers <= 1 || pieces <= 1 {
+		for i := 0; i < pieces; i++ {
+			doWork(i)
+		}
+		return
+	}
+	if workers > pieces {
+		workers = pieces
+	}
+
+	toProcess := make(chan int, pieces)
+	for i := 0; i < pieces; i++ {
+		toProcess <- i
+	}
+	close(toProcess)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for piece := range toProcess {
+				doWork(piece)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestParallelizeUntilVisitsEveryPiece(t *testing.T) {
+	const pieces = 50
+	var mu sync.Mutex
+	seen := make(map[int]bool, pieces)
+
+	parallelizeUntil(8, pieces, func(i int) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[i] = true
+	})
+
+	if len(seen) != pieces {
+		t.Fatalf("visited %d pieces, want %d", len(seen), pieces)
+	}
+}
+
+func TestParallelizeUntilSequentialFastPath(t *testing.T) {
+	var order []int
+	parallelizeUntil(1, 5, func(i int) {
+		order = append(order, i)
+	})
+	for i, v := range order {
+		if v != i {
+			t.Fatalf("order = %v, want sequential 0..4", order)
+		}
+	}
+}
+
+func TestParallelizeUntilNoPieces(t *testing.T) {
+	called := false
+	parallelizeUntil(4, 0, func(i int) {
+		called = true
+	})
+	if called {
+		t.Fatalf("doWork called with zero pieces")
+	}
+}
@@ -0,0 +1,346 @@
+/*
+Copyright 2014 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"container/heap"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/client/cache"
+)
+
+// PodPriorityAnnotation is the annotation key used to carry an explicit
+// scheduling priority on a pod. Higher values are scheduled first. Pods
+// without the annotation default to priority 0.
+const PodPriorityAnnotation = "scheduler.alpha.kubernetes.io/priority"
+
+// podPriority returns the scheduling priority of a pod, defaulting to 0 if
+// the pod carries no (or an unparsable) priority annotation.
+func podPriority(pod *api.Pod) int64 {
+	value, ok := pod.Annotations[PodPriorityAnnotation]
+	if !ok {
+		return 0
+	}
+	priority, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return priority
+}
+
+// priorityQueueItem is a single entry in the active heap.
+type priorityQueueItem struct {
+	pod   *api.Pod
+	index int
+}
+
+// podHeap implements container/heap.Interface over priorityQueueItems,
+// ordering highest priority first and falling back to FIFO order among
+// pods of equal priority.
+type podHeap struct {
+	items []*priorityQueueItem
+	seq   []int64
+
+	// nextSeq is a strictly-increasing counter handed out to each pushed
+	// item. It must not be derived from len(items): Pop shrinking the
+	// slice would let a later Push reuse a seq still held by an item
+	// already in the heap, breaking the FIFO tie-break guarantee.
+	nextSeq int64
+}
+
+func (h *podHeap) Len() int { return len(h.items) }
+
+func (h *podHeap) Less(i, j int) bool {
+	pi, pj := podPriority(h.items[i].pod), podPriority(h.items[j].pod)
+	if pi != pj {
+		return pi > pj
+	}
+	return h.seq[i] < h.seq[j]
+}
+
+func (h *podHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.seq[i], h.seq[j] = h.seq[j], h.seq[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *podHeap) Push(x interface{}) {
+	item := x.(*priorityQueueItem)
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+	h.seq = append(h.seq, h.nextSeq)
+	h.nextSeq++
+}
+
+func (h *podHeap) Pop() interface{} {
+	old := h.items
+	n := len(old)
+	item := old[n-1]
+	h.items = old[:n-1]
+	h.seq = h.seq[:n-1]
+	return item
+}
+
+// PriorityPodQueue is a scheduling queue that orders pending pods by
+// descending scheduling priority (see PodPriorityAnnotation) instead of
+// plain FIFO order. It implements cache.Store so it can be used directly
+// as a cache.Reflector target.
+//
+// Pods that failed a scheduling attempt are parked in a separate
+// unschedulable set rather than being requeued immediately; they are only
+// moved back onto the active heap when MoveAllToActiveQueue is called in
+// response to a cluster state change (node added/updated, pod deleted)
+// that might make them schedulable again.
+type PriorityPodQueue struct {
+	lock sync.Mutex
+	cond sync.Cond
+
+	// active is the heap of pods waiting for their first (or next) attempt.
+	active *podHeap
+	// keys indexes active by pod key so Add/Update can detect duplicates.
+	keys map[string]*priorityQueueItem
+
+	// unschedulable holds pods that failed a scheduling attempt, keyed by
+	// pod key, until a relevant cluster event flushes them back to active.
+	unschedulable map[string]*api.Pod
+
+	closed bool
+
+	// onDelete, if set, is called with a pod's key whenever it is removed
+	// from the queue via Delete, so callers can clear any per-pod state
+	// they keyed off the same pod (e.g. the scheduling latency tracker)
+	// instead of it growing unbounded for pods that never get bound.
+	onDelete func(key string)
+}
+
+// NewPriorityPodQueue creates an empty PriorityPodQueue.
+func NewPriorityPodQueue() *PriorityPodQueue {
+	pq := &PriorityPodQueue{
+		active:        &podHeap{},
+		keys:          map[string]*priorityQueueItem{},
+		unschedulable: map[string]*api.Pod{},
+	}
+	pq.cond.L = &pq.lock
+	return pq
+}
+
+func podKey(obj interface{}) (string, error) {
+	return cache.MetaNamespaceKeyFunc(obj)
+}
+
+// Add inserts or updates a pod on the active heap, satisfying cache.Store.
+// If the pod was parked in the unschedulable set, it is removed from there
+// first.
+func (q *PriorityPodQueue) Add(obj interface{}) error {
+	pod, ok := obj.(*api.Pod)
+	if !ok {
+		return fmt.Errorf("PriorityPodQueue only holds *api.Pod, got %T", obj)
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	key, err := podKey(pod)
+	if err != nil {
+		return err
+	}
+	delete(q.unschedulable, key)
+	if item, ok := q.keys[key]; ok {
+		item.pod = pod
+		heap.Fix(q.active, item.index)
+	} else {
+		item := &priorityQueueItem{pod: pod}
+		heap.Push(q.active, item)
+		q.keys[key] = item
+	}
+	q.cond.Broadcast()
+	return nil
+}
+
+// Update is equivalent to Add for this queue.
+func (q *PriorityPodQueue) Update(obj interface{}) error {
+	return q.Add(obj)
+}
+
+// AddUnschedulable parks a pod that just failed a scheduling attempt so it
+// is no longer retried on a tight backoff loop. It will be reconsidered
+// the next time MoveAllToActiveQueue runs.
+func (q *PriorityPodQueue) AddUnschedulable(pod *api.Pod) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	key, err := podKey(pod)
+	if err != nil {
+		return
+	}
+	q.unschedulable[key] = pod
+}
+
+// Delete removes a pod from either the active heap or the unschedulable
+// set, satisfying cache.Store.
+func (q *PriorityPodQueue) Delete(obj interface{}) error {
+	pod, ok := obj.(*api.Pod)
+	if !ok {
+		return fmt.Errorf("PriorityPodQueue only holds *api.Pod, got %T", obj)
+	}
+	key, err := podKey(pod)
+	if err != nil {
+		return err
+	}
+	q.lock.Lock()
+	delete(q.unschedulable, key)
+	if item, ok := q.keys[key]; ok {
+		heap.Remove(q.active, item.index)
+		delete(q.keys, key)
+	}
+	q.lock.Unlock()
+	if q.onDelete != nil {
+		q.onDelete(key)
+	}
+	return nil
+}
+
+// List returns every pod currently known to the queue, active or
+// unschedulable, satisfying cache.Store.
+func (q *PriorityPodQueue) List() []interface{} {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	result := make([]interface{}, 0, len(q.keys)+len(q.unschedulable))
+	for _, item := range q.keys {
+		result = append(result, item.pod)
+	}
+	for _, pod := range q.unschedulable {
+		result = append(result, pod)
+	}
+	return result
+}
+
+// ListKeys returns the keys of every pod currently known to the queue.
+func (q *PriorityPodQueue) ListKeys() []string {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	keys := make([]string, 0, len(q.keys)+len(q.unschedulable))
+	for key := range q.keys {
+		keys = append(keys, key)
+	}
+	for key := range q.unschedulable {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Get returns the pod matching obj's key, if any.
+func (q *PriorityPodQueue) Get(obj interface{}) (interface{}, bool, error) {
+	key, err := podKey(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	return q.GetByKey(key)
+}
+
+// GetByKey returns the pod stored under key, if any.
+func (q *PriorityPodQueue) GetByKey(key string) (interface{}, bool, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if item, ok := q.keys[key]; ok {
+		return item.pod, true, nil
+	}
+	if pod, ok := q.unschedulable[key]; ok {
+		return pod, true, nil
+	}
+	return nil, false, nil
+}
+
+// Replace implements cache.Store by resetting the active heap to exactly
+// the given pods. Any pods parked as unschedulable are dropped, matching
+// the semantics of a relist from the apiserver.
+func (q *PriorityPodQueue) Replace(objs []interface{}, resourceVersion string) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.active = &podHeap{}
+	q.keys = map[string]*priorityQueueItem{}
+	q.unschedulable = map[string]*api.Pod{}
+	for _, obj := range objs {
+		pod, ok := obj.(*api.Pod)
+		if !ok {
+			return fmt.Errorf("PriorityPodQueue only holds *api.Pod, got %T", obj)
+		}
+		key, err := podKey(pod)
+		if err != nil {
+			return err
+		}
+		item := &priorityQueueItem{pod: pod}
+		heap.Push(q.active, item)
+		q.keys[key] = item
+	}
+	q.cond.Broadcast()
+	return nil
+}
+
+// MoveAllToActiveQueue drains the unschedulable set back onto the active
+// heap. Callers hook this up to node add/update and pod delete events so
+// that pods only pay the cost of a retry once cluster state has actually
+// changed in a way that might let them fit.
+func (q *PriorityPodQueue) MoveAllToActiveQueue() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for key, pod := range q.unschedulable {
+		item := &priorityQueueItem{pod: pod}
+		heap.Push(q.active, item)
+		q.keys[key] = item
+		delete(q.unschedulable, key)
+	}
+	if len(q.active.items) > 0 {
+		q.cond.Broadcast()
+	}
+}
+
+// Pop blocks until a pod is available on the active heap, then returns the
+// highest priority one. Once Close has been called it returns nil, even if
+// pods are still queued.
+func (q *PriorityPodQueue) Pop() *api.Pod {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for !q.closed && q.active.Len() == 0 {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return nil
+	}
+	item := heap.Pop(q.active).(*priorityQueueItem)
+	if key, err := podKey(item.pod); err == nil {
+		delete(q.keys, key)
+	}
+	return item.pod
+}
+
+// Lengths returns the number of pods waiting on the active heap and parked
+// as unschedulable, for reporting queue-depth metrics.
+func (q *PriorityPodQueue) Lengths() (active, unschedulable int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.active.Len(), len(q.unschedulable)
+}
+
+// Close unblocks any goroutine waiting in Pop.
+func (q *PriorityPodQueue) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+)
+
+func podWithName(name string) *api.Pod {
+	return &api.Pod{ObjectMeta: api.ObjectMeta{Namespace: api.NamespaceDefault, Name: name}}
+}
+
+// TestPriorityPodQueueFIFOTieBreak verifies that equal-priority pods come
+// back out in the order they were pushed, even after an intervening Pop
+// has shrunk the heap. A seq derived from len(items) at push time would
+// let a later push reuse a seq still held by an item already in the heap.
+func TestPriorityPodQueueFIFOTieBreak(t *testing.T) {
+	q := NewPriorityPodQueue()
+
+	if err := q.Add(podWithName("a")); err != nil {
+		t.Fatalf("Add(a): %v", err)
+	}
+	if err := q.Add(podWithName("b")); err != nil {
+		t.Fatalf("Add(b): %v", err)
+	}
+	if err := q.Add(podWithName("c")); err != nil {
+		t.Fatalf("Add(c): %v", err)
+	}
+
+	if got := q.Pop().Name; got != "a" {
+		t.Fatalf("Pop() = %v, want a", got)
+	}
+
+	if err := q.Add(podWithName("d")); err != nil {
+		t.Fatalf("Add(d): %v", err)
+	}
+
+	for _, want := range []string{"b", "c", "d"} {
+		if got := q.Pop().Name; got != want {
+			t.Fatalf("Pop() = %v, want %v", got, want)
+		}
+	}
+}
+
+// TestPriorityPodQueuePriorityOrder verifies higher-priority pods are
+// always popped before lower-priority ones, regardless of push order.
+func TestPriorityPodQueuePriorityOrder(t *testing.T) {
+	q := NewPriorityPodQueue()
+
+	low := podWithName("low")
+	high := podWithName("high")
+	high.Annotations = map[string]string{PodPriorityAnnotation: "10"}
+
+	if err := q.Add(low); err != nil {
+		t.Fatalf("Add(low): %v", err)
+	}
+	if err := q.Add(high); err != nil {
+		t.Fatalf("Add(high): %v", err)
+	}
+
+	if got := q.Pop().Name; got != "high" {
+		t.Fatalf("Pop() = %v, want high", got)
+	}
+	if got := q.Pop().Name; got != "low" {
+		t.Fatalf("Pop() = %v, want low", got)
+	}
+}
+
+// TestPriorityPodQueueDeleteCallsOnDelete verifies Delete invokes the
+// onDelete hook so callers can clear any per-pod state (such as the
+// scheduling latency tracker) keyed off the same pod.
+func TestPriorityPodQueueDeleteCallsOnDelete(t *testing.T) {
+	q := NewPriorityPodQueue()
+	pod := podWithName("a")
+	if err := q.Add(pod); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	var deletedKey string
+	q.onDelete = func(key string) { deletedKey = key }
+
+	if err := q.Delete(pod); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if want := "default/a"; deletedKey != want {
+		t.Fatalf("onDelete key = %v, want %v", deletedKey, want)
+	}
+}
@@ -0,0 +1,373 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schedulercache holds the scheduler's view of cluster state: a
+// per-node snapshot of requested/allocatable resources and the pods
+// assigned to it, kept current from pod/node watch events rather than
+// rebuilt from the PodLister on every scheduling attempt.
+package schedulercache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+)
+
+// defaultAssumedPodTTL is how long an assumed (but not yet observed as
+// bound) pod is kept in the cache before it is forgotten. If the pod
+// never shows up in the scheduled-pod reflector within this window -
+// because the bind failed, or the apiserver write was lost - it is
+// evicted so it doesn't permanently inflate a node's requested resources.
+const defaultAssumedPodTTL = 30 * time.Second
+
+// Resource is a snapshot of the compute resources requested by the pods on
+// a node.
+type Resource struct {
+	MilliCPU int64
+	Memory   int64
+}
+
+// NodeInfo aggregates the information the scheduler's predicates and
+// priorities need about a single node, built incrementally from pod and
+// node watch events instead of re-listed on every scheduling attempt.
+type NodeInfo struct {
+	node *api.Node
+	pods []*api.Pod
+
+	requestedResource *Resource
+}
+
+// NewNodeInfo creates a NodeInfo with the given pods already accounted
+// for in its aggregated resource totals.
+func NewNodeInfo(pods ...*api.Pod) *NodeInfo {
+	ni := &NodeInfo{
+		requestedResource: &Resource{},
+	}
+	for _, pod := range pods {
+		ni.addPod(pod)
+	}
+	return ni
+}
+
+// Node returns the node this NodeInfo snapshot describes, or nil if the
+// node object has not been observed yet.
+func (n *NodeInfo) Node() *api.Node {
+	if n == nil {
+		return nil
+	}
+	return n.node
+}
+
+// Pods returns every pod currently assigned (or assumed to be assigned)
+// to this node.
+func (n *NodeInfo) Pods() []*api.Pod {
+	if n == nil {
+		return nil
+	}
+	return n.pods
+}
+
+// RequestedResource returns the aggregated resource requests of every pod
+// on this node.
+func (n *NodeInfo) RequestedResource() Resource {
+	if n == nil {
+		return Resource{}
+	}
+	return *n.requestedResource
+}
+
+// AllocatableResource returns the node's allocatable compute resources, or
+// a zero Resource if the node object itself hasn't been observed yet.
+func (n *NodeInfo) AllocatableResource() Resource {
+	if n == nil || n.node == nil {
+		return Resource{}
+	}
+	allocatable := n.node.Status.Allocatable
+	return Resource{
+		MilliCPU: allocatable.Cpu().MilliValue(),
+		Memory:   allocatable.Memory().Value(),
+	}
+}
+
+func (n *NodeInfo) addPod(pod *api.Pod) {
+	res := &Resource{}
+	for _, c := range pod.Spec.Containers {
+		res.MilliCPU += c.Resources.Requests.Cpu().MilliValue()
+		res.Memory += c.Resources.Requests.Memory().Value()
+	}
+	n.pods = append(n.pods, pod)
+	n.requestedResource.MilliCPU += res.MilliCPU
+	n.requestedResource.Memory += res.Memory
+}
+
+func (n *NodeInfo) removePod(pod *api.Pod) error {
+	for i := range n.pods {
+		if n.pods[i].Namespace == pod.Namespace && n.pods[i].Name == pod.Name {
+			for _, c := range pod.Spec.Containers {
+				n.requestedResource.MilliCPU -= c.Resources.Requests.Cpu().MilliValue()
+				n.requestedResource.Memory -= c.Resources.Requests.Memory().Value()
+			}
+			n.pods = append(n.pods[:i], n.pods[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("pod %v/%v not found on node", pod.Namespace, pod.Name)
+}
+
+func (n *NodeInfo) clone() *NodeInfo {
+	pods := append([]*api.Pod(nil), n.pods...)
+	res := *n.requestedResource
+	return &NodeInfo{node: n.node, pods: pods, requestedResource: &res}
+}
+
+// Cache is the scheduler's mutable view of the cluster, built from pod and
+// node watch events. It replaces repeatedly walking the PodLister on every
+// scheduling attempt with O(1) lookups of precomputed per-node state, and
+// lets the scheduler "assume" a pod is bound to a node as soon as it wins
+// a scheduling decision, before the apiserver write is even observed by
+// the scheduled-pod reflector.
+type Cache interface {
+	// AssumePod marks pod as bound to pod.Spec.Host for scheduling
+	// purposes, before the binding has actually been observed coming back
+	// through the scheduled-pod watch. The assumption expires after the
+	// cache's TTL unless AddPod observes the pod first.
+	AssumePod(pod *api.Pod) error
+
+	// FinishBinding records that the bind call for a previously assumed
+	// pod returned, restarting its TTL; the pod is still expired if AddPod
+	// does not observe it within that window.
+	FinishBinding(pod *api.Pod) error
+
+	// ForgetPod removes an assumed pod that will never show up, e.g.
+	// because the bind call failed.
+	ForgetPod(pod *api.Pod) error
+
+	// AddPod adds a pod observed (e.g. via the scheduled-pod reflector) to
+	// its node's snapshot. If the pod was previously assumed, this simply
+	// confirms the assumption instead of double-counting it.
+	AddPod(pod *api.Pod) error
+
+	// UpdatePod updates a pod already known to the cache.
+	UpdatePod(oldPod, newPod *api.Pod) error
+
+	// RemovePod removes a pod from its node's snapshot.
+	RemovePod(pod *api.Pod) error
+
+	// AddNode creates an empty NodeInfo snapshot for a newly observed node.
+	AddNode(node *api.Node) error
+
+	// UpdateNode refreshes the node object stored in an existing NodeInfo
+	// snapshot without touching the pods recorded against it.
+	UpdateNode(oldNode, newNode *api.Node) error
+
+	// RemoveNode drops a node's snapshot entirely.
+	RemoveNode(node *api.Node) error
+
+	// GetNodeInfo returns the current snapshot for a node, or nil if the
+	// node is unknown.
+	GetNodeInfo(nodeName string) *NodeInfo
+
+	// List returns every pod known to the cache, scheduled or assumed.
+	List() []*api.Pod
+}
+
+type assumedPod struct {
+	pod      *api.Pod
+	deadline time.Time
+}
+
+type schedulerCache struct {
+	ttl time.Duration
+
+	mu    sync.Mutex
+	nodes map[string]*NodeInfo
+	// assumed indexes the subset of pods in nodes that were added via
+	// AssumePod and have not yet been observed via AddPod.
+	assumed map[string]*assumedPod
+}
+
+// New creates an empty Cache. stop, when closed, ends the background
+// goroutine that expires stale assumed pods; callers typically pass the
+// same channel used to stop the scheduler's reflectors.
+func New(ttl time.Duration, stop <-chan struct{}) Cache {
+	if ttl <= 0 {
+		ttl = defaultAssumedPodTTL
+	}
+	cache := &schedulerCache{
+		ttl:     ttl,
+		nodes:   map[string]*NodeInfo{},
+		assumed: map[string]*assumedPod{},
+	}
+	go cache.run(stop)
+	return cache
+}
+
+func podKey(pod *api.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
+
+func (c *schedulerCache) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.expireAssumedPods()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (c *schedulerCache) expireAssumedPods() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for key, a := range c.assumed {
+		if now.After(a.deadline) {
+			if info, ok := c.nodes[a.pod.Spec.Host]; ok {
+				info.removePod(a.pod)
+			}
+			delete(c.assumed, key)
+		}
+	}
+}
+
+func (c *schedulerCache) AssumePod(pod *api.Pod) error {
+	if pod.Spec.Host == "" {
+		return fmt.Errorf("pod %v/%v has no assigned host to assume", pod.Namespace, pod.Name)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, ok := c.nodes[pod.Spec.Host]
+	if !ok {
+		info = NewNodeInfo()
+		c.nodes[pod.Spec.Host] = info
+	}
+	info.addPod(pod)
+	c.assumed[podKey(pod)] = &assumedPod{pod: pod, deadline: time.Now().Add(c.ttl)}
+	return nil
+}
+
+func (c *schedulerCache) FinishBinding(pod *api.Pod) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if a, ok := c.assumed[podKey(pod)]; ok {
+		a.deadline = time.Now().Add(c.ttl)
+	}
+	return nil
+}
+
+func (c *schedulerCache) ForgetPod(pod *api.Pod) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.assumed, podKey(pod))
+	if info, ok := c.nodes[pod.Spec.Host]; ok {
+		return info.removePod(pod)
+	}
+	return nil
+}
+
+func (c *schedulerCache) AddPod(pod *api.Pod) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.assumed, podKey(pod))
+	info, ok := c.nodes[pod.Spec.Host]
+	if !ok {
+		info = NewNodeInfo()
+		c.nodes[pod.Spec.Host] = info
+	}
+	// An assumed pod for this node may already be counted; only add if it
+	// isn't already present.
+	for _, p := range info.pods {
+		if p.Namespace == pod.Namespace && p.Name == pod.Name {
+			return nil
+		}
+	}
+	info.addPod(pod)
+	return nil
+}
+
+func (c *schedulerCache) UpdatePod(oldPod, newPod *api.Pod) error {
+	if err := c.RemovePod(oldPod); err != nil {
+		return err
+	}
+	return c.AddPod(newPod)
+}
+
+func (c *schedulerCache) RemovePod(pod *api.Pod) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.assumed, podKey(pod))
+	info, ok := c.nodes[pod.Spec.Host]
+	if !ok {
+		return nil
+	}
+	return info.removePod(pod)
+}
+
+func (c *schedulerCache) AddNode(node *api.Node) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, ok := c.nodes[node.Name]
+	if !ok {
+		info = NewNodeInfo()
+		c.nodes[node.Name] = info
+	}
+	info.node = node
+	return nil
+}
+
+func (c *schedulerCache) UpdateNode(oldNode, newNode *api.Node) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, ok := c.nodes[newNode.Name]
+	if !ok {
+		info = NewNodeInfo()
+		c.nodes[newNode.Name] = info
+	}
+	info.node = newNode
+	return nil
+}
+
+func (c *schedulerCache) RemoveNode(node *api.Node) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.nodes, node.Name)
+	return nil
+}
+
+func (c *schedulerCache) GetNodeInfo(nodeName string) *NodeInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, ok := c.nodes[nodeName]
+	if !ok {
+		return nil
+	}
+	return info.clone()
+}
+
+func (c *schedulerCache) List() []*api.Pod {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var pods []*api.Pod
+	for _, info := range c.nodes {
+		pods = append(pods, info.pods...)
+	}
+	return pods
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2015 Google Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedulercache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api/resource"
+)
+
+func testPod(name, host string) *api.Pod {
+	return &api.Pod{
+		ObjectMeta: api.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       api.PodSpec{Host: host},
+	}
+}
+
+func TestAssumePodThenFinishBinding(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	cache := New(time.Hour, stop)
+
+	pod := testPod("a", "node1")
+	if err := cache.AssumePod(pod); err != nil {
+		t.Fatalf("AssumePod: %v", err)
+	}
+	if got := len(cache.GetNodeInfo("node1").Pods()); got != 1 {
+		t.Fatalf("node1 pods = %v, want 1", got)
+	}
+	if err := cache.FinishBinding(pod); err != nil {
+		t.Fatalf("FinishBinding: %v", err)
+	}
+	// FinishBinding only confirms the assumption; the pod stays on the node.
+	if got := len(cache.GetNodeInfo("node1").Pods()); got != 1 {
+		t.Fatalf("node1 pods after FinishBinding = %v, want 1", got)
+	}
+}
+
+func TestForgetPodRemovesItFromNode(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	cache := New(time.Hour, stop)
+
+	pod := testPod("a", "node1")
+	if err := cache.AssumePod(pod); err != nil {
+		t.Fatalf("AssumePod: %v", err)
+	}
+	if err := cache.ForgetPod(pod); err != nil {
+		t.Fatalf("ForgetPod: %v", err)
+	}
+	if got := len(cache.GetNodeInfo("node1").Pods()); got != 0 {
+		t.Fatalf("node1 pods after ForgetPod = %v, want 0", got)
+	}
+}
+
+func TestAssumedPodExpiresAfterTTL(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	cache := New(20*time.Millisecond, stop)
+
+	pod := testPod("a", "node1")
+	if err := cache.AssumePod(pod); err != nil {
+		t.Fatalf("AssumePod: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if len(cache.GetNodeInfo("node1").Pods()) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("assumed pod was never expired from node1")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAllocatableResource(t *testing.T) {
+	ni := NewNodeInfo()
+	if got := ni.AllocatableResource(); got != (Resource{}) {
+		t.Fatalf("AllocatableResource before AddNode = %+v, want zero value", got)
+	}
+
+	node := &api.Node{
+		ObjectMeta: api.ObjectMeta{Name: "node1"},
+		Status: api.NodeStatus{
+			Allocatable: api.ResourceList{
+				api.ResourceCPU:    resource.MustParse("2"),
+				api.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	cache := New(time.Hour, stop)
+	if err := cache.AddNode(node); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+
+	got := cache.GetNodeInfo("node1").AllocatableResource()
+	want := Resource{MilliCPU: 2000, Memory: 4 * 1024 * 1024 * 1024}
+	if got != want {
+		t.Fatalf("AllocatableResource = %+v, want %+v", got, want)
+	}
+}
+
+func TestAddPodDoesNotDoubleCountAnAlreadyAssumedPod(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	cache := New(time.Hour, stop)
+
+	pod := testPod("a", "node1")
+	if err := cache.AssumePod(pod); err != nil {
+		t.Fatalf("AssumePod: %v", err)
+	}
+	if err := cache.AddPod(pod); err != nil {
+		t.Fatalf("AddPod: %v", err)
+	}
+	if got := len(cache.GetNodeInfo("node1").Pods()); got != 1 {
+		t.Fatalf("node1 pods = %v, want 1", got)
+	}
+}